@@ -0,0 +1,66 @@
+package main
+
+import (
+	"crypto/subtle"
+	"net/http"
+	"strings"
+	"token-bucket-rate-limiter/limiter"
+
+	"github.com/gin-gonic/gin"
+	log "github.com/sirupsen/logrus"
+)
+
+// adminAuth guards a route group with a static bearer token from env, e.g.
+// "Authorization: Bearer <token>". It's intentionally simple: one shared
+// token for all admin operations, not per-operator credentials.
+func adminAuth(token string) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		const prefix = "Bearer "
+		header := c.GetHeader("Authorization")
+		if !strings.HasPrefix(header, prefix) {
+			c.JSON(http.StatusUnauthorized, gin.H{"error": "Unauthorized"})
+			c.Abort()
+			return
+		}
+
+		presented := header[len(prefix):]
+		if len(presented) != len(token) || subtle.ConstantTimeCompare([]byte(presented), []byte(token)) != 1 {
+			c.JSON(http.StatusUnauthorized, gin.H{"error": "Unauthorized"})
+			c.Abort()
+			return
+		}
+		c.Next()
+	}
+}
+
+// RegisterAdminRoutes mounts the whitelist/blacklist management endpoints
+// under /admin, guarded by a bearer token, so operators can respond to an
+// incident (block an abusive IP, unblock a false positive) without a
+// deploy.
+func RegisterAdminRoutes(r *gin.Engine, admission *limiter.AdmissionList, token string) {
+	admin := r.Group("/admin", adminAuth(token))
+
+	admin.POST("/whitelist/:ip", func(c *gin.Context) {
+		ip := c.Param("ip")
+		if err := admission.Whitelist(c.Request.Context(), ip); err != nil {
+			log.WithFields(log.Fields{"ip": ip, "error": err}).Error("Failed to whitelist IP")
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Internal server error"})
+			return
+		}
+		c.JSON(http.StatusOK, gin.H{"whitelisted": ip})
+	})
+
+	admin.DELETE("/blacklist/:ip", func(c *gin.Context) {
+		ip := c.Param("ip")
+		if err := admission.Unblacklist(c.Request.Context(), ip); err != nil {
+			log.WithFields(log.Fields{"ip": ip, "error": err}).Error("Failed to remove IP from blacklist")
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Internal server error"})
+			return
+		}
+		c.JSON(http.StatusOK, gin.H{"unblacklisted": ip})
+	})
+
+	admin.GET("/rules", func(c *gin.Context) {
+		c.JSON(http.StatusOK, admission.Snapshot())
+	})
+}
@@ -0,0 +1,120 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"net"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+)
+
+// KeyFunc extracts the key a request should be rate-limited by, e.g. a
+// client IP, an API key, or an authenticated user ID.
+type KeyFunc func(c *gin.Context) (string, error)
+
+// KeyByIP rate-limits by the client's IP address.
+func KeyByIP(c *gin.Context) (string, error) {
+	ip := c.ClientIP()
+	if ip == "" {
+		return "", errors.New("unable to determine client IP")
+	}
+	return ip, nil
+}
+
+// KeyByForwardedFor rate-limits by the right-most address in X-Forwarded-For
+// that isn't in trustedProxies, falling back to the client IP if the header
+// is absent or every hop is trusted. Only use this behind proxies you
+// control: an X-Forwarded-For value from an untrusted hop can be spoofed by
+// the client.
+func KeyByForwardedFor(trustedProxies []string) KeyFunc {
+	trusted := make(map[string]struct{}, len(trustedProxies))
+	for _, proxy := range trustedProxies {
+		trusted[proxy] = struct{}{}
+	}
+
+	return func(c *gin.Context) (string, error) {
+		hops := strings.Split(c.GetHeader("X-Forwarded-For"), ",")
+		for i := len(hops) - 1; i >= 0; i-- {
+			hop := strings.TrimSpace(hops[i])
+			if hop == "" {
+				continue
+			}
+			if _, ok := trusted[hop]; ok {
+				continue
+			}
+			if net.ParseIP(hop) == nil {
+				continue
+			}
+			return hop, nil
+		}
+		return KeyByIP(c)
+	}
+}
+
+// KeyByHeader rate-limits by the raw value of an arbitrary header, e.g. an
+// API key.
+func KeyByHeader(header string) KeyFunc {
+	return func(c *gin.Context) (string, error) {
+		value := c.GetHeader(header)
+		if value == "" {
+			return "", fmt.Errorf("missing %s header", header)
+		}
+		return value, nil
+	}
+}
+
+// KeyByContextValue rate-limits by a string previously stashed on the gin
+// context under contextKey, e.g. a JWT "sub" claim or authenticated user ID
+// set by an earlier auth middleware. It does not itself verify anything.
+func KeyByContextValue(contextKey string) KeyFunc {
+	return func(c *gin.Context) (string, error) {
+		value, ok := c.Get(contextKey)
+		if !ok {
+			return "", fmt.Errorf("no value found under context key %q", contextKey)
+		}
+		str, ok := value.(string)
+		if !ok || str == "" {
+			return "", fmt.Errorf("context key %q did not hold a non-empty string", contextKey)
+		}
+		return str, nil
+	}
+}
+
+// KeyByRoute rate-limits by the matched route pattern (e.g. "/api/:id").
+// Mainly useful composed with another KeyFunc via Composite.
+func KeyByRoute(c *gin.Context) (string, error) {
+	return c.FullPath(), nil
+}
+
+// Composite combines multiple KeyFuncs into one — e.g.
+// Composite(KeyByContextValue("user_id"), KeyByRoute) limits each user
+// independently per route.
+func Composite(keyFuncs ...KeyFunc) KeyFunc {
+	return func(c *gin.Context) (string, error) {
+		parts := make([]string, len(keyFuncs))
+		for i, keyFunc := range keyFuncs {
+			part, err := keyFunc(c)
+			if err != nil {
+				return "", err
+			}
+			parts[i] = part
+		}
+		return encodeKeyParts(parts...), nil
+	}
+}
+
+// encodeKeyParts joins parts into a single rate-limiter key, length-prefixing
+// each one ("<byte length>:<part>:") rather than joining with a plain
+// separator, so a part containing ":" (an IPv6 address, a route pattern like
+// "/api/:id") can never be mistaken for a separator and make two different
+// part combinations collide on one key. Any call site that builds a key out
+// of more than one value should go through this rather than ad hoc
+// concatenation.
+func encodeKeyParts(parts ...string) string {
+	var b strings.Builder
+	for _, part := range parts {
+		fmt.Fprintf(&b, "%d:%s:", len(part), part)
+	}
+	return b.String()
+}
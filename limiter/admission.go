@@ -0,0 +1,186 @@
+package limiter
+
+import (
+	"context"
+	"sync"
+	"time"
+)
+
+// Whitelist and blacklist keys are plain Redis sets of whatever string a
+// KeyFunc produces (usually an IP), so operators can manage them with any
+// Redis client, not just through AdmissionList's Whitelist/Unblacklist.
+const (
+	whitelistKey = "rate_limit:whitelist"
+	blacklistKey = "rate_limit:blacklist"
+)
+
+// defaultAdmissionRefreshInterval is how often AdmissionList polls Redis for
+// whitelist/blacklist changes made out-of-band (e.g. by another process, or
+// directly in Redis).
+const defaultAdmissionRefreshInterval = 5 * time.Second
+
+// AdmissionConfig configures an AdmissionList's refresh cadence.
+type AdmissionConfig struct {
+	RefreshInterval time.Duration
+}
+
+// AdmissionList caches the rate_limit:whitelist and rate_limit:blacklist
+// Redis sets in memory, refreshing them periodically in the background so
+// IsWhitelisted/IsBlacklisted never block on Redis on the request path.
+// Whitelisted keys bypass the limiter entirely; blacklisted keys should be
+// rejected before the limiter is even consulted.
+type AdmissionList struct {
+	client RedisClient
+	cfg    AdmissionConfig
+
+	mu        sync.RWMutex
+	whitelist map[string]struct{}
+	blacklist map[string]struct{}
+
+	stopOnce sync.Once
+	stopCh   chan struct{}
+	wg       sync.WaitGroup
+}
+
+// NewAdmissionList builds an AdmissionList, performs an initial load, and
+// starts the background refresh loop.
+func NewAdmissionList(client RedisClient, cfg AdmissionConfig) *AdmissionList {
+	if cfg.RefreshInterval <= 0 {
+		cfg.RefreshInterval = defaultAdmissionRefreshInterval
+	}
+
+	al := &AdmissionList{
+		client:    client,
+		cfg:       cfg,
+		whitelist: make(map[string]struct{}),
+		blacklist: make(map[string]struct{}),
+		stopCh:    make(chan struct{}),
+	}
+
+	al.refresh(context.Background())
+
+	al.wg.Add(1)
+	go al.refreshLoop()
+
+	return al
+}
+
+// IsWhitelisted reports whether key was in the whitelist as of the last
+// refresh.
+func (al *AdmissionList) IsWhitelisted(key string) bool {
+	al.mu.RLock()
+	defer al.mu.RUnlock()
+	_, ok := al.whitelist[key]
+	return ok
+}
+
+// IsBlacklisted reports whether key was in the blacklist as of the last
+// refresh.
+func (al *AdmissionList) IsBlacklisted(key string) bool {
+	al.mu.RLock()
+	defer al.mu.RUnlock()
+	_, ok := al.blacklist[key]
+	return ok
+}
+
+// Rules is a snapshot of the whitelist and blacklist as of the last refresh,
+// for callers (e.g. an admin endpoint) that want to display the current
+// state.
+type Rules struct {
+	Whitelist []string
+	Blacklist []string
+}
+
+// Snapshot returns the current whitelist and blacklist.
+func (al *AdmissionList) Snapshot() Rules {
+	al.mu.RLock()
+	defer al.mu.RUnlock()
+
+	rules := Rules{
+		Whitelist: make([]string, 0, len(al.whitelist)),
+		Blacklist: make([]string, 0, len(al.blacklist)),
+	}
+	for key := range al.whitelist {
+		rules.Whitelist = append(rules.Whitelist, key)
+	}
+	for key := range al.blacklist {
+		rules.Blacklist = append(rules.Blacklist, key)
+	}
+	return rules
+}
+
+// Whitelist adds key to the whitelist in Redis and in the local cache, so
+// the caller observes the change immediately rather than waiting for the
+// next refresh.
+func (al *AdmissionList) Whitelist(ctx context.Context, key string) error {
+	if err := al.client.SAdd(ctx, whitelistKey, key).Err(); err != nil {
+		return err
+	}
+	al.mu.Lock()
+	al.whitelist[key] = struct{}{}
+	al.mu.Unlock()
+	return nil
+}
+
+// Unblacklist removes key from the blacklist in Redis and in the local
+// cache.
+func (al *AdmissionList) Unblacklist(ctx context.Context, key string) error {
+	if err := al.client.SRem(ctx, blacklistKey, key).Err(); err != nil {
+		return err
+	}
+	al.mu.Lock()
+	delete(al.blacklist, key)
+	al.mu.Unlock()
+	return nil
+}
+
+// Close stops the background refresh loop.
+func (al *AdmissionList) Close() {
+	al.stopOnce.Do(func() { close(al.stopCh) })
+	al.wg.Wait()
+}
+
+func (al *AdmissionList) refreshLoop() {
+	defer al.wg.Done()
+
+	ticker := time.NewTicker(al.cfg.RefreshInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-al.stopCh:
+			return
+		case <-ticker.C:
+			al.refresh(context.Background())
+		}
+	}
+}
+
+// refresh polls both sets via SMEMBERS and swaps them into the cache.
+// Keyspace notifications would avoid the poll, but require cluster-wide
+// notify-keyspace-events configuration the limiter package can't guarantee,
+// so a periodic poll is the simpler default.
+func (al *AdmissionList) refresh(ctx context.Context) {
+	whitelist, err := al.client.SMembers(ctx, whitelistKey).Result()
+	if err != nil {
+		return
+	}
+	blacklist, err := al.client.SMembers(ctx, blacklistKey).Result()
+	if err != nil {
+		return
+	}
+
+	whitelistSet := make(map[string]struct{}, len(whitelist))
+	for _, key := range whitelist {
+		whitelistSet[key] = struct{}{}
+	}
+	blacklistSet := make(map[string]struct{}, len(blacklist))
+	for _, key := range blacklist {
+		blacklistSet[key] = struct{}{}
+	}
+
+	al.mu.Lock()
+	al.whitelist = whitelistSet
+	al.blacklist = blacklistSet
+	al.mu.Unlock()
+}
@@ -0,0 +1,49 @@
+package limiter
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/go-redis/redis/v8"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestAdmissionList_RefreshesWhitelistAndBlacklist(t *testing.T) {
+	ctx := context.Background()
+	client := redis.NewClient(&redis.Options{Addr: "localhost:6379", DB: 1})
+	defer client.FlushDB(ctx)
+
+	assert.NoError(t, client.SAdd(ctx, whitelistKey, "1.2.3.4").Err())
+	assert.NoError(t, client.SAdd(ctx, blacklistKey, "5.6.7.8").Err())
+
+	al := NewAdmissionList(client, AdmissionConfig{RefreshInterval: 20 * time.Millisecond})
+	defer al.Close()
+
+	assert.True(t, al.IsWhitelisted("1.2.3.4"))
+	assert.True(t, al.IsBlacklisted("5.6.7.8"))
+	assert.False(t, al.IsWhitelisted("5.6.7.8"))
+
+	assert.NoError(t, client.SAdd(ctx, blacklistKey, "9.9.9.9").Err())
+	time.Sleep(100 * time.Millisecond)
+	assert.True(t, al.IsBlacklisted("9.9.9.9"), "expected the background refresh to pick up an out-of-band change")
+}
+
+func TestAdmissionList_WhitelistAndUnblacklistUpdateImmediately(t *testing.T) {
+	ctx := context.Background()
+	client := redis.NewClient(&redis.Options{Addr: "localhost:6379", DB: 1})
+	defer client.FlushDB(ctx)
+
+	al := NewAdmissionList(client, AdmissionConfig{RefreshInterval: time.Hour})
+	defer al.Close()
+
+	assert.NoError(t, al.Whitelist(ctx, "1.2.3.4"))
+	assert.True(t, al.IsWhitelisted("1.2.3.4"), "expected Whitelist to update the cache without waiting for a refresh")
+
+	assert.NoError(t, client.SAdd(ctx, blacklistKey, "5.6.7.8").Err())
+	al.refresh(ctx)
+	assert.True(t, al.IsBlacklisted("5.6.7.8"))
+
+	assert.NoError(t, al.Unblacklist(ctx, "5.6.7.8"))
+	assert.False(t, al.IsBlacklisted("5.6.7.8"), "expected Unblacklist to update the cache without waiting for a refresh")
+}
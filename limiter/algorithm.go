@@ -0,0 +1,55 @@
+package limiter
+
+import (
+	"context"
+	"time"
+)
+
+// Context is the outcome of a single Algorithm.Allow call: the configured
+// limit, how much headroom remains, when that headroom next changes, and
+// whether this request was denied for having hit the limit. It's exported so
+// callers writing their own middleware can render rate-limit headers
+// themselves instead of going through a higher-level helper.
+type Context struct {
+	Limit     int64
+	Remaining float64
+	Reset     time.Time
+	Reached   bool
+}
+
+// Algorithm implements one rate-limiting strategy (token bucket, fixed
+// window, ...) against a Store. Algorithms hold no per-key state themselves;
+// it all lives in the Store, so a single Algorithm value can be shared
+// across keys and, via Redis, across processes.
+type Algorithm interface {
+	Allow(ctx context.Context, store Store, key string, rate Rate) (Context, error)
+}
+
+// Limiter ties a Store and an Algorithm to a Rate: the backend, the
+// strategy, and the rate itself are independent axes, and a Limiter is just
+// one point in that space.
+type Limiter struct {
+	store      Store
+	algorithm  Algorithm
+	rateSource RateSource
+}
+
+// New builds a Limiter from a Store, an Algorithm and the Rate to enforce.
+// The rate is fixed for the Limiter's lifetime; use NewWithRateSource for a
+// Rate that can be reconfigured live.
+func New(store Store, algorithm Algorithm, rate Rate) *Limiter {
+	return NewWithRateSource(store, algorithm, StaticRate(rate))
+}
+
+// NewWithRateSource builds a Limiter whose Rate is supplied by rateSource on
+// every call, e.g. a DynamicRate kept fresh from Redis so operators can
+// retune capacity without restarting the process.
+func NewWithRateSource(store Store, algorithm Algorithm, rateSource RateSource) *Limiter {
+	return &Limiter{store: store, algorithm: algorithm, rateSource: rateSource}
+}
+
+// Allow reports whether the request identified by key may proceed under the
+// configured rate, atomically updating the underlying Store.
+func (l *Limiter) Allow(ctx context.Context, key string) (Context, error) {
+	return l.algorithm.Allow(ctx, l.store, key, l.rateSource.Rate())
+}
@@ -0,0 +1,66 @@
+package limiter
+
+import (
+	"context"
+	"encoding/json"
+	"time"
+)
+
+// FixedWindow counts requests in discrete, non-overlapping windows of
+// rate.Period. It's the cheapest algorithm to store, at the cost of allowing
+// up to 2x the limit across a window boundary.
+type FixedWindow struct{}
+
+// NewFixedWindow returns a fixed-window Algorithm.
+func NewFixedWindow() FixedWindow {
+	return FixedWindow{}
+}
+
+type fixedWindowState struct {
+	Count       int64 `json:"count"`
+	WindowStart int64 `json:"window_start"`
+}
+
+// Allow implements Algorithm.
+func (FixedWindow) Allow(ctx context.Context, store Store, key string, rate Rate) (Context, error) {
+	now := time.Now()
+	periodSeconds := periodSeconds(rate)
+	windowStart := now.Unix() - (now.Unix() % periodSeconds)
+
+	result, err := store.Mutate(ctx, key, rate.Period, func(current []byte) ([]byte, interface{}, error) {
+		state := fixedWindowState{WindowStart: windowStart}
+		if len(current) > 0 {
+			if err := json.Unmarshal(current, &state); err != nil {
+				return nil, nil, err
+			}
+			if state.WindowStart != windowStart {
+				state = fixedWindowState{WindowStart: windowStart}
+			}
+		}
+
+		ctxResult := Context{Limit: rate.Limit, Reset: time.Unix(windowStart+periodSeconds, 0)}
+		if state.Count < rate.Limit {
+			state.Count++
+		} else {
+			ctxResult.Reached = true
+		}
+		ctxResult.Remaining = float64(rate.Limit - state.Count)
+
+		next, err := json.Marshal(state)
+		return next, ctxResult, err
+	})
+	if err != nil {
+		return Context{}, err
+	}
+	return result.(Context), nil
+}
+
+// periodSeconds returns rate.Period in whole seconds, floored to 1 so a
+// sub-second period can't produce a zero-width window.
+func periodSeconds(rate Rate) int64 {
+	seconds := int64(rate.Period.Seconds())
+	if seconds < 1 {
+		seconds = 1
+	}
+	return seconds
+}
@@ -0,0 +1,83 @@
+package limiter
+
+import (
+	"container/list"
+	"context"
+	"sync"
+	"time"
+)
+
+// MemoryStore is an in-process Store backed by a bounded LRU cache with a
+// per-entry TTL. It needs no external dependency, making it a good default
+// for single-node deployments and a fallback when Redis is unreachable.
+type MemoryStore struct {
+	mu       sync.Mutex
+	capacity int
+	entries  map[string]*list.Element
+	order    *list.List
+}
+
+type memoryEntry struct {
+	key       string
+	value     []byte
+	expiresAt time.Time
+}
+
+// NewMemoryStore creates a MemoryStore that evicts its oldest entry once
+// more than capacity keys are being tracked. A non-positive capacity means
+// unbounded.
+func NewMemoryStore(capacity int) *MemoryStore {
+	return &MemoryStore{
+		capacity: capacity,
+		entries:  make(map[string]*list.Element),
+		order:    list.New(),
+	}
+}
+
+// Mutate implements Store. Since it runs entirely under s.mu, fn always sees
+// a consistent value with no retries needed.
+func (s *MemoryStore) Mutate(ctx context.Context, key string, ttl time.Duration, fn func(current []byte) ([]byte, interface{}, error)) (interface{}, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	var current []byte
+	if el, ok := s.entries[key]; ok {
+		entry := el.Value.(*memoryEntry)
+		if time.Now().Before(entry.expiresAt) {
+			current = entry.value
+		} else {
+			s.order.Remove(el)
+			delete(s.entries, key)
+		}
+	}
+
+	next, result, err := fn(current)
+	if err != nil {
+		return nil, err
+	}
+
+	expiresAt := time.Now().Add(ttl)
+	if el, ok := s.entries[key]; ok {
+		entry := el.Value.(*memoryEntry)
+		entry.value, entry.expiresAt = next, expiresAt
+		s.order.MoveToFront(el)
+	} else {
+		el := s.order.PushFront(&memoryEntry{key: key, value: next, expiresAt: expiresAt})
+		s.entries[key] = el
+		s.evictOldest()
+	}
+
+	return result, nil
+}
+
+// evictOldest drops entries beyond s.capacity. Callers must hold s.mu.
+func (s *MemoryStore) evictOldest() {
+	for s.capacity > 0 && len(s.entries) > s.capacity {
+		oldest := s.order.Back()
+		if oldest == nil {
+			return
+		}
+		s.order.Remove(oldest)
+		delete(s.entries, oldest.Value.(*memoryEntry).key)
+	}
+}
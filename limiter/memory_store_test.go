@@ -0,0 +1,83 @@
+package limiter
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestMemoryStore_EvictsOldestBeyondCapacity(t *testing.T) {
+	ctx := context.Background()
+	store := NewMemoryStore(2)
+
+	touch := func(key string) {
+		_, err := store.Mutate(ctx, key, time.Minute, func(current []byte) ([]byte, interface{}, error) {
+			return []byte("v"), nil, nil
+		})
+		assert.NoError(t, err)
+	}
+
+	touch("a")
+	touch("b")
+	touch("c") // should evict "a"
+
+	// Inspect the backing map directly: Mutate is the store's only
+	// operation and always counts as a touch, so probing presence through
+	// it would itself perturb LRU order.
+	_, stillPresent := store.entries["a"]
+	assert.False(t, stillPresent, "expected oldest entry to be evicted")
+	_, stillPresent = store.entries["b"]
+	assert.True(t, stillPresent)
+	_, stillPresent = store.entries["c"]
+	assert.True(t, stillPresent)
+}
+
+func TestMemoryStore_ExpiresEntries(t *testing.T) {
+	ctx := context.Background()
+	store := NewMemoryStore(10)
+
+	_, err := store.Mutate(ctx, "k", 50*time.Millisecond, func(current []byte) ([]byte, interface{}, error) {
+		return []byte("v"), nil, nil
+	})
+	assert.NoError(t, err)
+
+	time.Sleep(100 * time.Millisecond)
+
+	var sawExpired bool
+	_, err = store.Mutate(ctx, "k", time.Minute, func(current []byte) ([]byte, interface{}, error) {
+		sawExpired = current == nil
+		return []byte("v2"), nil, nil
+	})
+	assert.NoError(t, err)
+	assert.True(t, sawExpired, "expected expired entry to look absent to the caller")
+}
+
+func TestAlgorithms_AllowUpToLimitThenDeny(t *testing.T) {
+	ctx := context.Background()
+	rate := Rate{Limit: 3, Period: time.Minute}
+
+	algorithms := map[string]Algorithm{
+		"FixedWindow":          NewFixedWindow(),
+		"SlidingWindowLog":     NewSlidingWindowLog(),
+		"SlidingWindowCounter": NewSlidingWindowCounter(),
+	}
+
+	for name, algorithm := range algorithms {
+		algorithm := algorithm
+		t.Run(name, func(t *testing.T) {
+			l := New(NewMemoryStore(10), algorithm, rate)
+
+			for i := 0; i < int(rate.Limit); i++ {
+				rateLimitCtx, err := l.Allow(ctx, "key")
+				assert.NoError(t, err)
+				assert.False(t, rateLimitCtx.Reached, "expected request %d to be allowed", i+1)
+			}
+
+			rateLimitCtx, err := l.Allow(ctx, "key")
+			assert.NoError(t, err)
+			assert.True(t, rateLimitCtx.Reached, "expected request beyond the limit to be denied")
+		})
+	}
+}
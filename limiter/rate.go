@@ -0,0 +1,52 @@
+package limiter
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Rate expresses a limit as "N requests per period", e.g. 100 requests
+// every minute, replacing the separate capacity/refill-rate/ttl floats the
+// original token bucket took.
+type Rate struct {
+	Limit  int64
+	Period time.Duration
+}
+
+// periodUnits maps the single-letter period suffix used by ParseRate to its
+// duration.
+var periodUnits = map[byte]time.Duration{
+	'S': time.Second,
+	'M': time.Minute,
+	'H': time.Hour,
+	'D': 24 * time.Hour,
+}
+
+// ParseRate parses the compact "<limit>-<period>" format (e.g. "100-M" for
+// 100 requests per minute, "10-S" for 10 per second), so operators can
+// configure a limiter from a single env var.
+func ParseRate(formatted string) (Rate, error) {
+	parts := strings.SplitN(formatted, "-", 2)
+	if len(parts) != 2 || len(parts[1]) != 1 {
+		return Rate{}, fmt.Errorf("limiter: invalid rate %q, expected \"<limit>-<period>\" e.g. \"100-M\"", formatted)
+	}
+
+	limit, err := strconv.ParseInt(parts[0], 10, 64)
+	if err != nil {
+		return Rate{}, fmt.Errorf("limiter: invalid rate limit in %q: %w", formatted, err)
+	}
+
+	period, ok := periodUnits[strings.ToUpper(parts[1])[0]]
+	if !ok {
+		return Rate{}, fmt.Errorf("limiter: invalid rate period %q, expected one of S, M, H, D", parts[1])
+	}
+
+	return Rate{Limit: limit, Period: period}, nil
+}
+
+// RefillRate returns the number of tokens accrued per second under this rate.
+func (r Rate) RefillRate() float64 {
+	return float64(r.Limit) / r.Period.Seconds()
+}
@@ -0,0 +1,129 @@
+package limiter
+
+import (
+	"context"
+	"log"
+	"sync"
+	"time"
+)
+
+// RateSource supplies the Rate a Limiter enforces. StaticRate covers the
+// common case of a Rate fixed at construction time; DynamicRate lets
+// operators change it live via Redis.
+type RateSource interface {
+	Rate() Rate
+}
+
+// StaticRate is a RateSource that always returns the same Rate.
+type StaticRate Rate
+
+// Rate implements RateSource.
+func (s StaticRate) Rate() Rate { return Rate(s) }
+
+// dynamicConfigKey is the Redis hash operators write to reconfigure a
+// DynamicRate live, and dynamicConfigField the hash field holding the new
+// rate in ParseRate's compact "<limit>-<period>" format.
+const (
+	dynamicConfigKey   = "rate_limit:config"
+	dynamicConfigField = "rate"
+)
+
+// defaultDynamicRateRefreshInterval is how often DynamicRate polls Redis for
+// a new rate_limit:config value.
+const defaultDynamicRateRefreshInterval = 5 * time.Second
+
+// DynamicRateConfig configures a DynamicRate's refresh cadence.
+type DynamicRateConfig struct {
+	RefreshInterval time.Duration
+}
+
+// DynamicRate is a RateSource backed by the rate_limit:config Redis hash,
+// refreshed periodically in the background so a Limiter's capacity and
+// refill rate can be retuned during an incident without a restart. It starts
+// at the Rate it was constructed with, and keeps the last Rate it loaded
+// whenever Redis is unreachable, the key is missing, or the stored value
+// doesn't parse.
+type DynamicRate struct {
+	client   RedisClient
+	fallback Rate
+	cfg      DynamicRateConfig
+
+	mu      sync.RWMutex
+	current Rate
+
+	stopOnce sync.Once
+	stopCh   chan struct{}
+	wg       sync.WaitGroup
+}
+
+// NewDynamicRate builds a DynamicRate that starts at fallback and begins
+// polling rate_limit:config in the background.
+func NewDynamicRate(client RedisClient, fallback Rate, cfg DynamicRateConfig) *DynamicRate {
+	if cfg.RefreshInterval <= 0 {
+		cfg.RefreshInterval = defaultDynamicRateRefreshInterval
+	}
+
+	dr := &DynamicRate{
+		client:   client,
+		fallback: fallback,
+		cfg:      cfg,
+		current:  fallback,
+		stopCh:   make(chan struct{}),
+	}
+
+	dr.refresh(context.Background())
+
+	dr.wg.Add(1)
+	go dr.refreshLoop()
+
+	return dr
+}
+
+// Rate implements RateSource.
+func (dr *DynamicRate) Rate() Rate {
+	dr.mu.RLock()
+	defer dr.mu.RUnlock()
+	return dr.current
+}
+
+// Close stops the background refresh loop.
+func (dr *DynamicRate) Close() {
+	dr.stopOnce.Do(func() { close(dr.stopCh) })
+	dr.wg.Wait()
+}
+
+func (dr *DynamicRate) refreshLoop() {
+	defer dr.wg.Done()
+
+	ticker := time.NewTicker(dr.cfg.RefreshInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-dr.stopCh:
+			return
+		case <-ticker.C:
+			dr.refresh(context.Background())
+		}
+	}
+}
+
+// refresh reads the current rate_limit:config value and, if it parses,
+// swaps it in. A missing key or a value that fails to parse leaves the
+// last-known-good rate (or the constructor's fallback) in place.
+func (dr *DynamicRate) refresh(ctx context.Context) {
+	value, err := dr.client.HGet(ctx, dynamicConfigKey, dynamicConfigField).Result()
+	if err != nil {
+		return
+	}
+
+	rate, err := ParseRate(value)
+	if err != nil {
+		log.Printf("[DynamicRate] Ignoring unparseable %s.%s = %q: %v", dynamicConfigKey, dynamicConfigField, value, err)
+		return
+	}
+
+	dr.mu.Lock()
+	dr.current = rate
+	dr.mu.Unlock()
+}
@@ -0,0 +1,30 @@
+package limiter
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/go-redis/redis/v8"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestDynamicRate_FallsBackOnBadValue(t *testing.T) {
+	ctx := context.Background()
+	client := redis.NewClient(&redis.Options{Addr: "localhost:6379", DB: 1})
+	defer client.FlushDB(ctx)
+
+	fallback := Rate{Limit: 10, Period: time.Minute}
+	dr := NewDynamicRate(client, fallback, DynamicRateConfig{RefreshInterval: 20 * time.Millisecond})
+	defer dr.Close()
+
+	assert.Equal(t, fallback, dr.Rate())
+
+	assert.NoError(t, client.HSet(ctx, dynamicConfigKey, dynamicConfigField, "not-a-rate").Err())
+	time.Sleep(100 * time.Millisecond)
+	assert.Equal(t, fallback, dr.Rate(), "expected an unparseable config value to be ignored rather than crash or zero out the rate")
+
+	assert.NoError(t, client.HSet(ctx, dynamicConfigKey, dynamicConfigField, "50-S").Err())
+	time.Sleep(100 * time.Millisecond)
+	assert.Equal(t, Rate{Limit: 50, Period: time.Second}, dr.Rate(), "expected a valid config value to be picked up")
+}
@@ -0,0 +1,25 @@
+package limiter
+
+import (
+	"context"
+	"time"
+
+	"github.com/go-redis/redis/v8"
+)
+
+// RedisClient is the subset of redis.Cmdable that RedisStore, TieredLimiter,
+// AdmissionList and DynamicRate need, plus Ping. *redis.Client (standalone or Sentinel-backed via
+// NewFailoverClient) and *redis.ClusterClient both satisfy it, so callers
+// can point a limiter at whichever topology they run without the limiter
+// package caring which.
+type RedisClient interface {
+	redis.Scripter
+	Get(ctx context.Context, key string) *redis.StringCmd
+	Set(ctx context.Context, key string, value interface{}, expiration time.Duration) *redis.StatusCmd
+	Pipeline() redis.Pipeliner
+	Ping(ctx context.Context) *redis.StatusCmd
+	SMembers(ctx context.Context, key string) *redis.StringSliceCmd
+	SAdd(ctx context.Context, key string, members ...interface{}) *redis.IntCmd
+	SRem(ctx context.Context, key string, members ...interface{}) *redis.IntCmd
+	HGet(ctx context.Context, key, field string) *redis.StringCmd
+}
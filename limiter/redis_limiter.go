@@ -2,71 +2,43 @@ package limiter
 
 import (
 	"context"
-	"fmt"
-	"log"
-	"math"
 	"time"
-
-	"github.com/go-redis/redis/v8"
 )
 
-// RedisTokenBucket defines the rate limiter structure
+// RedisTokenBucket is a token-bucket Limiter backed by Redis. It's kept as a
+// named type, rather than callers using New directly, for backwards
+// compatibility with existing construction and AllowRequest call sites.
 type RedisTokenBucket struct {
-	client     *redis.Client
-	capacity   float64       // Max tokens per IP
-	refillRate float64       // Tokens added per second
-	ttl        time.Duration // Expiration time for IP entries
+	*Limiter
 }
 
-// NewRedisTokenBucket initializes a Redis-backed token bucket with TTL support
-func NewRedisTokenBucket(client *redis.Client, capacity, refillRate float64, ttl time.Duration) *RedisTokenBucket {
-	return &RedisTokenBucket{
-		client:     client,
-		capacity:   capacity,
-		refillRate: refillRate,
-		ttl:        ttl,
-	}
+// NewRedisTokenBucket builds a token-bucket Limiter against Redis for the
+// given rate. client may be a standalone, Sentinel-backed, or cluster client.
+func NewRedisTokenBucket(client RedisClient, rate Rate) *RedisTokenBucket {
+	return &RedisTokenBucket{Limiter: New(NewRedisStore(client), NewTokenBucket(), rate)}
 }
 
-// getIPKey generates a Redis key for an IP
-func (rtb *RedisTokenBucket) getIPKey(ip string) string {
-	return fmt.Sprintf("rate_limit:ip:%s", ip)
+// NewRedisTokenBucketWithRateSource is NewRedisTokenBucket for a Rate that
+// can change at runtime, e.g. a DynamicRate kept fresh from Redis.
+func NewRedisTokenBucketWithRateSource(client RedisClient, rateSource RateSource) *RedisTokenBucket {
+	return &RedisTokenBucket{Limiter: NewWithRateSource(NewRedisStore(client), NewTokenBucket(), rateSource)}
 }
 
-// AllowRequest checks if an IP can proceed with a request
-func (rtb *RedisTokenBucket) AllowRequest(ctx context.Context, ip string) bool {
-	redisKey := rtb.getIPKey(ip)
-	now := time.Now().Unix()
-
-	// Fetch IP-specific rate limit data from Redis
-	tokens, err := rtb.client.Get(ctx, redisKey+":tokens").Float64()
-	if err != nil {
-		tokens = rtb.capacity // Default to full bucket if key doesn't exist
-	}
+// getIPKey generates a Redis key for an IP.
+func getIPKey(ip string) string {
+	return "rate_limit:ip:" + ip
+}
 
-	lastUpdated, err := rtb.client.Get(ctx, redisKey+":last_updated").Int64()
+// AllowRequest checks if an IP can proceed with a request. remaining is the
+// token count left after this call, and retryAfter is how long the caller
+// should wait before the next token becomes available (zero when allowed).
+func (rtb *RedisTokenBucket) AllowRequest(ctx context.Context, ip string) (allowed bool, remaining float64, retryAfter time.Duration, err error) {
+	ctxResult, err := rtb.Allow(ctx, getIPKey(ip))
 	if err != nil {
-		lastUpdated = now
+		return false, 0, 0, err
 	}
-
-	// Calculate elapsed time and refill tokens
-	elapsed := float64(now - lastUpdated)
-	newTokens := math.Min(rtb.capacity, tokens+(elapsed*rtb.refillRate))
-
-	// Allow request only if at least 1 token is available
-	if newTokens >= 1 {
-		pipe := rtb.client.TxPipeline()
-		pipe.Set(ctx, redisKey+":tokens", newTokens-1, rtb.ttl) // Set TTL for cleanup
-		pipe.Set(ctx, redisKey+":last_updated", now, rtb.ttl)   // Ensure timestamp also expires
-		_, err = pipe.Exec(ctx)
-		if err != nil {
-			log.Printf("[RedisLimiter] Error updating Redis: %v", err)
-			return false
-		}
-		return true
+	if !ctxResult.Reached {
+		return true, ctxResult.Remaining, 0, nil
 	}
-
-	// Log rejections
-	log.Printf("[RedisLimiter] Request rejected for IP %s. Tokens left: %.2f", ip, newTokens)
-	return false
+	return false, ctxResult.Remaining, time.Until(ctxResult.Reset), nil
 }
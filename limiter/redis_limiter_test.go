@@ -2,6 +2,8 @@ package limiter
 
 import (
 	"context"
+	"sync"
+	"sync/atomic"
 	"testing"
 	"time"
 
@@ -19,24 +21,32 @@ func TestAllowRequest_BasicFunctionality(t *testing.T) {
 	})
 	defer client.FlushDB(ctx) // Cleanup after test
 
-	// Initialize rate limiter with small limits for testing
-	rateLimiter := NewRedisTokenBucket(client, 3, 1, 10*time.Second)
+	// Initialize rate limiter with small limits for testing: 3 tokens,
+	// refilling at 1/sec.
+	rateLimiter := NewRedisTokenBucket(client, Rate{Limit: 3, Period: 3 * time.Second})
 
 	ip := "192.168.1.100"
 
 	// First 3 requests should pass
-	assert.True(t, rateLimiter.AllowRequest(ctx, ip), "Expected request 1 to pass")
-	assert.True(t, rateLimiter.AllowRequest(ctx, ip), "Expected request 2 to pass")
-	assert.True(t, rateLimiter.AllowRequest(ctx, ip), "Expected request 3 to pass")
+	for i := 0; i < 3; i++ {
+		allowed, _, _, err := rateLimiter.AllowRequest(ctx, ip)
+		assert.NoError(t, err)
+		assert.True(t, allowed, "Expected request %d to pass", i+1)
+	}
 
 	// 4th request should be rejected
-	assert.False(t, rateLimiter.AllowRequest(ctx, ip), "Expected request 4 to be rate limited")
+	allowed, _, retryAfter, err := rateLimiter.AllowRequest(ctx, ip)
+	assert.NoError(t, err)
+	assert.False(t, allowed, "Expected request 4 to be rate limited")
+	assert.Greater(t, retryAfter, time.Duration(0))
 
 	// Wait for token refill (1 second)
 	time.Sleep(1 * time.Second)
 
 	// Now 1 request should pass again
-	assert.True(t, rateLimiter.AllowRequest(ctx, ip), "Expected request after refill to pass")
+	allowed, _, _, err = rateLimiter.AllowRequest(ctx, ip)
+	assert.NoError(t, err)
+	assert.True(t, allowed, "Expected request after refill to pass")
 }
 
 func TestAllowRequest_TTLExpiry(t *testing.T) {
@@ -47,17 +57,69 @@ func TestAllowRequest_TTLExpiry(t *testing.T) {
 	})
 	defer client.FlushDB(ctx)
 
-	rateLimiter := NewRedisTokenBucket(client, 2, 1, 5*time.Second)
+	// 2 tokens refilling at 1/sec, so the key's TTL (tied to the period) is
+	// 2 seconds.
+	rateLimiter := NewRedisTokenBucket(client, Rate{Limit: 2, Period: 2 * time.Second})
 	ip := "192.168.1.200"
 
 	// Consume all tokens
-	assert.True(t, rateLimiter.AllowRequest(ctx, ip))
-	assert.True(t, rateLimiter.AllowRequest(ctx, ip))
-	assert.False(t, rateLimiter.AllowRequest(ctx, ip)) // Should be blocked
+	allowed, _, _, err := rateLimiter.AllowRequest(ctx, ip)
+	assert.NoError(t, err)
+	assert.True(t, allowed)
+
+	allowed, _, _, err = rateLimiter.AllowRequest(ctx, ip)
+	assert.NoError(t, err)
+	assert.True(t, allowed)
+
+	allowed, _, _, err = rateLimiter.AllowRequest(ctx, ip)
+	assert.NoError(t, err)
+	assert.False(t, allowed) // Should be blocked
 
 	// Wait for TTL to expire
-	time.Sleep(6 * time.Second)
+	time.Sleep(3 * time.Second)
 
 	// Now IP should be reset, and requests should pass again
-	assert.True(t, rateLimiter.AllowRequest(ctx, ip), "Expected request after TTL expiry to pass")
+	allowed, _, _, err = rateLimiter.AllowRequest(ctx, ip)
+	assert.NoError(t, err)
+	assert.True(t, allowed, "Expected request after TTL expiry to pass")
+}
+
+// TestAllowRequest_ConcurrentRequestsRespectCapacity hammers the same IP from
+// many goroutines at once to prove the CAS loop keeps the GET/compute/SET
+// cycle atomic: exactly `capacity` requests should be allowed, never more.
+func TestAllowRequest_ConcurrentRequestsRespectCapacity(t *testing.T) {
+	ctx := context.Background()
+	client := redis.NewClient(&redis.Options{
+		Addr: "localhost:6379",
+		DB:   1,
+	})
+	defer client.FlushDB(ctx)
+
+	const capacity = 20
+	const attempts = 200
+
+	// A long period keeps the refill rate low enough (capacity/period tokens
+	// per second) that no token can refill during the test, whether or not
+	// it happens to straddle a wall-clock second boundary: the invariant
+	// under test is the CAS loop's atomicity, not the refill math.
+	rateLimiter := NewRedisTokenBucket(client, Rate{Limit: capacity, Period: time.Hour})
+	ip := "192.168.1.300"
+
+	var wg sync.WaitGroup
+	var allowedCount int64
+
+	for i := 0; i < attempts; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			allowed, _, _, err := rateLimiter.AllowRequest(ctx, ip)
+			assert.NoError(t, err)
+			if allowed {
+				atomic.AddInt64(&allowedCount, 1)
+			}
+		}()
+	}
+	wg.Wait()
+
+	assert.EqualValues(t, capacity, allowedCount, "expected exactly the bucket's capacity to be allowed under concurrent load")
 }
@@ -0,0 +1,89 @@
+package limiter
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"time"
+
+	"github.com/go-redis/redis/v8"
+)
+
+// casScript atomically replaces the value at KEYS[1] with ARGV[2] if its
+// current value still matches ARGV[1] (the empty string means "absent"),
+// refreshing its TTL to ARGV[3] seconds. It returns 1 on a successful swap
+// and 0 on a conflicting concurrent write, so RedisStore can retry without
+// ever losing an update.
+const casScript = `
+local current = redis.call("GET", KEYS[1])
+if current == false then current = "" end
+
+if current ~= ARGV[1] then
+	return 0
+end
+
+redis.call("SET", KEYS[1], ARGV[2], "EX", ARGV[3])
+return 1
+`
+
+// maxCASAttempts bounds retries under heavy contention on a single key;
+// giving up beyond this points to a stuck client rather than ordinary
+// contention. Sized generously: a goroutine only needs one more attempt
+// than the number of concurrent writers that can interleave ahead of it
+// between its GET and its CAS, so a few hundred concurrent callers hammering
+// one key (see TestAllowRequest_ConcurrentRequestsRespectCapacity) should
+// stay well clear of this ceiling.
+const maxCASAttempts = 1000
+
+// RedisStore is a Store backed by Redis, suitable for rate limiting shared
+// across multiple processes.
+type RedisStore struct {
+	client RedisClient
+	cas    *redis.Script
+}
+
+// NewRedisStore wraps an existing Redis client as a Store. client may be a
+// standalone, Sentinel-backed, or cluster client.
+func NewRedisStore(client RedisClient) *RedisStore {
+	store := &RedisStore{client: client, cas: redis.NewScript(casScript)}
+	if err := store.cas.Load(context.Background(), client).Err(); err != nil {
+		log.Printf("[RedisStore] Failed to pre-load CAS script: %v", err)
+	}
+	return store
+}
+
+// Mutate implements Store using an optimistic GET-compute-CAS loop: fn runs
+// locally against the last-seen value, and casScript only commits the
+// result if nothing else wrote to key in the meantime.
+func (s *RedisStore) Mutate(ctx context.Context, key string, ttl time.Duration, fn func(current []byte) ([]byte, interface{}, error)) (interface{}, error) {
+	ttlSeconds := int64(ttl.Seconds())
+	if ttlSeconds < 1 {
+		ttlSeconds = 1
+	}
+
+	for attempt := 0; attempt < maxCASAttempts; attempt++ {
+		current, err := s.client.Get(ctx, key).Bytes()
+		if err != nil {
+			if !errors.Is(err, redis.Nil) {
+				return nil, err
+			}
+			current = nil
+		}
+
+		next, result, err := fn(current)
+		if err != nil {
+			return nil, err
+		}
+
+		swapped, err := s.cas.Run(ctx, s.client, []string{key}, string(current), string(next), ttlSeconds).Bool()
+		if err != nil {
+			return nil, err
+		}
+		if swapped {
+			return result, nil
+		}
+	}
+
+	return nil, fmt.Errorf("limiter: exceeded %d CAS attempts on key %q", maxCASAttempts, key)
+}
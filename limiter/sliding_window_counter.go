@@ -0,0 +1,68 @@
+package limiter
+
+import (
+	"context"
+	"encoding/json"
+	"time"
+)
+
+// SlidingWindowCounter approximates a sliding window using two adjacent
+// fixed windows: it weights the previous window's count by how much of it
+// still overlaps the trailing rate.Period, giving near-exact limiting at
+// fixed-window storage cost.
+type SlidingWindowCounter struct{}
+
+// NewSlidingWindowCounter returns a sliding-window-counter Algorithm.
+func NewSlidingWindowCounter() SlidingWindowCounter {
+	return SlidingWindowCounter{}
+}
+
+type slidingCounterState struct {
+	CurrentStart  int64 `json:"current_start"`
+	CurrentCount  int64 `json:"current_count"`
+	PreviousCount int64 `json:"previous_count"`
+}
+
+// Allow implements Algorithm.
+func (SlidingWindowCounter) Allow(ctx context.Context, store Store, key string, rate Rate) (Context, error) {
+	now := time.Now()
+	periodSeconds := periodSeconds(rate)
+	windowStart := now.Unix() - (now.Unix() % periodSeconds)
+
+	result, err := store.Mutate(ctx, key, rate.Period, func(current []byte) ([]byte, interface{}, error) {
+		state := slidingCounterState{CurrentStart: windowStart}
+		if len(current) > 0 {
+			if err := json.Unmarshal(current, &state); err != nil {
+				return nil, nil, err
+			}
+			switch state.CurrentStart {
+			case windowStart:
+				// Same window as last time, nothing to roll.
+			case windowStart - periodSeconds:
+				state.PreviousCount = state.CurrentCount
+				state.CurrentStart = windowStart
+				state.CurrentCount = 0
+			default:
+				state = slidingCounterState{CurrentStart: windowStart}
+			}
+		}
+
+		elapsedInWindow := float64(now.Unix()-windowStart) / float64(periodSeconds)
+		weighted := float64(state.PreviousCount)*(1-elapsedInWindow) + float64(state.CurrentCount)
+
+		ctxResult := Context{Limit: rate.Limit, Remaining: float64(rate.Limit) - weighted, Reset: time.Unix(windowStart+periodSeconds, 0)}
+		if weighted < float64(rate.Limit) {
+			state.CurrentCount++
+			ctxResult.Remaining--
+		} else {
+			ctxResult.Reached = true
+		}
+
+		next, err := json.Marshal(state)
+		return next, ctxResult, err
+	})
+	if err != nil {
+		return Context{}, err
+	}
+	return result.(Context), nil
+}
@@ -0,0 +1,63 @@
+package limiter
+
+import (
+	"context"
+	"encoding/json"
+	"time"
+)
+
+// SlidingWindowLog keeps one timestamp per request made in the trailing
+// rate.Period and allows a new request only if fewer than rate.Limit
+// timestamps remain once entries older than the window are dropped. Exact,
+// but its storage cost grows with the limit.
+type SlidingWindowLog struct{}
+
+// NewSlidingWindowLog returns a sliding-window-log Algorithm.
+func NewSlidingWindowLog() SlidingWindowLog {
+	return SlidingWindowLog{}
+}
+
+// Allow implements Algorithm.
+func (SlidingWindowLog) Allow(ctx context.Context, store Store, key string, rate Rate) (Context, error) {
+	now := time.Now()
+	cutoff := now.Add(-rate.Period).UnixNano()
+
+	result, err := store.Mutate(ctx, key, rate.Period, func(current []byte) ([]byte, interface{}, error) {
+		var hits []int64
+		if len(current) > 0 {
+			if err := json.Unmarshal(current, &hits); err != nil {
+				return nil, nil, err
+			}
+		}
+
+		kept := hits[:0]
+		for _, ts := range hits {
+			if ts > cutoff {
+				kept = append(kept, ts)
+			}
+		}
+
+		ctxResult := Context{Limit: rate.Limit, Remaining: float64(rate.Limit - int64(len(kept)))}
+		if int64(len(kept)) < rate.Limit {
+			kept = append(kept, now.UnixNano())
+			ctxResult.Remaining--
+		} else {
+			ctxResult.Reached = true
+		}
+
+		// Reset is when the oldest entry still in the window falls out of
+		// it, i.e. the earliest moment Remaining can increase.
+		if len(kept) > 0 {
+			ctxResult.Reset = time.Unix(0, kept[0]).Add(rate.Period)
+		} else {
+			ctxResult.Reset = now
+		}
+
+		next, err := json.Marshal(kept)
+		return next, ctxResult, err
+	})
+	if err != nil {
+		return Context{}, err
+	}
+	return result.(Context), nil
+}
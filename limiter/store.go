@@ -0,0 +1,19 @@
+package limiter
+
+import (
+	"context"
+	"time"
+)
+
+// Store is the backend an Algorithm reads and writes its per-key state in.
+// Implementations must make Mutate atomic with respect to concurrent callers
+// on the same key, since that is the only primitive Algorithm implementations
+// rely on for correctness.
+type Store interface {
+	// Mutate atomically loads the raw state previously stored at key (nil if
+	// the key is absent or expired), passes it to fn, and persists whatever
+	// fn returns as the new state, expiring it after ttl. The interface{}
+	// fn returns alongside the next state is passed back to the caller
+	// unchanged, letting algorithms surface their own result type through it.
+	Mutate(ctx context.Context, key string, ttl time.Duration, fn func(current []byte) (next []byte, result interface{}, err error)) (interface{}, error)
+}
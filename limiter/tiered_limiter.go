@@ -0,0 +1,402 @@
+package limiter
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"log"
+	"math"
+	"strconv"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/go-redis/redis/v8"
+)
+
+// tieredFlushScript atomically applies a batch of locally-consumed tokens
+// (ARGV[5], always <= 0) to the authoritative bucket at KEYS[1]/KEYS[2],
+// refilling it for elapsed time first, and returns the reconciled token
+// count so the caller can write it back into the local cache.
+const tieredFlushScript = `
+local tokensKey = KEYS[1]
+local updatedKey = KEYS[2]
+
+local capacity = tonumber(ARGV[1])
+local refillRate = tonumber(ARGV[2])
+local now = tonumber(ARGV[3])
+local ttl = tonumber(ARGV[4])
+local delta = tonumber(ARGV[5])
+
+local tokens = tonumber(redis.call("GET", tokensKey))
+if tokens == nil then tokens = capacity end
+
+local lastUpdated = tonumber(redis.call("GET", updatedKey))
+if lastUpdated == nil then lastUpdated = now end
+
+local elapsed = math.max(0, now - lastUpdated)
+local refreshed = math.min(capacity, tokens + elapsed * refillRate)
+local newTokens = math.max(0, math.min(capacity, refreshed + delta))
+
+redis.call("SET", tokensKey, newTokens, "EX", ttl)
+redis.call("SET", updatedKey, now, "EX", ttl)
+
+return tostring(newTokens)
+`
+
+// tieredDebitScript is tieredFlushScript's single-request counterpart: it
+// refills KEYS[1]/KEYS[2] for elapsed time and atomically debits one token
+// if available, so StrictRedis/LocalOnlyOnRedisDown reads and LocalFirst's
+// background reconciliation agree on exactly one storage format.
+const tieredDebitScript = `
+local tokensKey = KEYS[1]
+local updatedKey = KEYS[2]
+
+local capacity = tonumber(ARGV[1])
+local refillRate = tonumber(ARGV[2])
+local now = tonumber(ARGV[3])
+local ttl = tonumber(ARGV[4])
+
+local tokens = tonumber(redis.call("GET", tokensKey))
+if tokens == nil then tokens = capacity end
+
+local lastUpdated = tonumber(redis.call("GET", updatedKey))
+if lastUpdated == nil then lastUpdated = now end
+
+local elapsed = math.max(0, now - lastUpdated)
+local refreshed = math.min(capacity, tokens + elapsed * refillRate)
+
+local allowed = 0
+if refreshed >= 1 then
+	allowed = 1
+	refreshed = refreshed - 1
+end
+
+redis.call("SET", tokensKey, refreshed, "EX", ttl)
+redis.call("SET", updatedKey, now, "EX", ttl)
+
+return {allowed, tostring(refreshed)}
+`
+
+// tieredKeys returns the pair of Redis keys holding key's token-bucket state
+// under a dedicated rate_limit:tiered: namespace. Every TieredLimiter Mode
+// goes through this one helper, so switching Mode never loses state to an
+// incompatible layout and caller keys never collide with unrelated data in
+// the same Redis DB.
+func tieredKeys(key string) (tokensKey, updatedKey string) {
+	base := "rate_limit:tiered:" + key
+	return base + ":tokens", base + ":last_updated"
+}
+
+// Mode controls how far a TieredLimiter trusts its local cache before
+// consulting Redis.
+type Mode int
+
+const (
+	// StrictRedis answers every request from Redis directly, skipping the
+	// local cache entirely.
+	StrictRedis Mode = iota
+	// LocalFirst answers from the local cache immediately and reconciles
+	// with Redis in the background. Highest throughput, briefly eventually
+	// consistent across processes.
+	LocalFirst
+	// LocalOnlyOnRedisDown answers from Redis like StrictRedis as long as
+	// it's reachable, and falls back to the local cache only once Redis
+	// calls start failing.
+	LocalOnlyOnRedisDown
+)
+
+// TierConfig configures a TieredLimiter's local cache and reconciliation
+// cadence.
+type TierConfig struct {
+	FlushInterval time.Duration // how often pending deltas are reconciled with Redis
+	MaxBatch      int           // max number of dirty keys reconciled per flush pass
+	LocalCapacity int           // bounded size of the local LRU cache
+	Mode          Mode
+}
+
+const (
+	defaultFlushInterval = 100 * time.Millisecond
+	defaultLocalCapacity = 10000
+)
+
+// tieredState is a key's local view of its token bucket: Tokens/LastUpdated
+// mirror tokenBucketState, and PendingDelta tracks tokens consumed locally
+// since the last successful reconciliation with Redis.
+type tieredState struct {
+	Tokens       float64 `json:"tokens"`
+	LastUpdated  int64   `json:"last_updated"`
+	PendingDelta float64 `json:"pending_delta"`
+}
+
+// TieredLimiter is a token-bucket limiter with a per-process in-memory front
+// cache and an eventually-consistent Redis back store, for deployments where
+// a per-request Redis round trip is the bottleneck. In LocalFirst mode, and
+// in LocalOnlyOnRedisDown mode once Redis calls have failed, it answers from
+// the local cache and reconciles asynchronously, so concurrent processes may
+// briefly disagree on the exact remaining count.
+type TieredLimiter struct {
+	cfg         TierConfig
+	rate        Rate
+	local       *MemoryStore
+	client      RedisClient
+	flushScript *redis.Script
+	debitScript *redis.Script
+
+	redisHealthy atomic.Bool
+
+	dirtyMu sync.Mutex
+	dirty   map[string]struct{}
+
+	stopOnce sync.Once
+	stopCh   chan struct{}
+	wg       sync.WaitGroup
+}
+
+// NewTieredLimiter builds a TieredLimiter enforcing rate, using client
+// (standalone, Sentinel-backed, or cluster) as the authoritative Redis
+// backend.
+func NewTieredLimiter(client RedisClient, rate Rate, cfg TierConfig) *TieredLimiter {
+	if cfg.FlushInterval <= 0 {
+		cfg.FlushInterval = defaultFlushInterval
+	}
+	if cfg.LocalCapacity <= 0 {
+		cfg.LocalCapacity = defaultLocalCapacity
+	}
+
+	tl := &TieredLimiter{
+		cfg:         cfg,
+		rate:        rate,
+		local:       NewMemoryStore(cfg.LocalCapacity),
+		client:      client,
+		flushScript: redis.NewScript(tieredFlushScript),
+		debitScript: redis.NewScript(tieredDebitScript),
+		dirty:       make(map[string]struct{}),
+		stopCh:      make(chan struct{}),
+	}
+	tl.redisHealthy.Store(true)
+
+	if err := tl.flushScript.Load(context.Background(), client).Err(); err != nil {
+		log.Printf("[TieredLimiter] Failed to pre-load flush script: %v", err)
+	}
+	if err := tl.debitScript.Load(context.Background(), client).Err(); err != nil {
+		log.Printf("[TieredLimiter] Failed to pre-load debit script: %v", err)
+	}
+
+	if cfg.Mode != StrictRedis {
+		tl.wg.Add(1)
+		go tl.flushLoop()
+	}
+
+	return tl
+}
+
+// Allow reports whether the request identified by key may proceed, per the
+// configured Mode.
+func (tl *TieredLimiter) Allow(ctx context.Context, key string) (Context, error) {
+	switch tl.cfg.Mode {
+	case StrictRedis:
+		return tl.redisAllow(ctx, key)
+	case LocalOnlyOnRedisDown:
+		if tl.redisHealthy.Load() {
+			ctxResult, err := tl.redisAllow(ctx, key)
+			if err == nil {
+				return ctxResult, nil
+			}
+			tl.redisHealthy.Store(false)
+		}
+		return tl.decideLocally(ctx, key)
+	default: // LocalFirst
+		return tl.decideLocally(ctx, key)
+	}
+}
+
+// redisAllow debits one token directly against the authoritative Redis
+// bucket via tieredDebitScript, using the same rate_limit:tiered: key layout
+// LocalFirst's background reconciliation writes, so all three Modes share
+// one consistent view of a key's state.
+func (tl *TieredLimiter) redisAllow(ctx context.Context, key string) (Context, error) {
+	now := time.Now()
+	tokensKey, updatedKey := tieredKeys(key)
+	refillRate := tl.rate.RefillRate()
+
+	raw, err := tl.debitScript.Run(ctx, tl.client, []string{tokensKey, updatedKey},
+		float64(tl.rate.Limit), refillRate, now.Unix(), periodSeconds(tl.rate)).Result()
+	if err != nil {
+		return Context{}, err
+	}
+
+	result, ok := raw.([]interface{})
+	if !ok || len(result) != 2 {
+		return Context{}, fmt.Errorf("limiter: unexpected tieredDebitScript result %#v", raw)
+	}
+	allowed, _ := result[0].(int64)
+	remaining, err := strconv.ParseFloat(result[1].(string), 64)
+	if err != nil {
+		return Context{}, err
+	}
+
+	ctxResult := Context{Limit: tl.rate.Limit, Remaining: remaining, Reached: allowed == 0}
+	secondsToNextToken := math.Max(0, math.Ceil((1-remaining)/refillRate))
+	ctxResult.Reset = now.Add(time.Duration(secondsToNextToken) * time.Second)
+	return ctxResult, nil
+}
+
+// Close stops the background flusher. It is a no-op in StrictRedis mode,
+// which never starts one.
+func (tl *TieredLimiter) Close() {
+	if tl.cfg.Mode == StrictRedis {
+		return
+	}
+	tl.stopOnce.Do(func() { close(tl.stopCh) })
+	tl.wg.Wait()
+}
+
+// decideLocally refills and debits the local bucket for key immediately,
+// recording the consumed token as a pending delta for the background
+// flusher to reconcile with Redis.
+func (tl *TieredLimiter) decideLocally(ctx context.Context, key string) (Context, error) {
+	now := time.Now()
+	capacity := float64(tl.rate.Limit)
+	refillRate := tl.rate.RefillRate()
+
+	result, err := tl.local.Mutate(ctx, key, tl.rate.Period, func(current []byte) ([]byte, interface{}, error) {
+		state := tieredState{Tokens: capacity, LastUpdated: now.Unix()}
+		if len(current) > 0 {
+			if err := json.Unmarshal(current, &state); err != nil {
+				return nil, nil, err
+			}
+		}
+
+		elapsed := math.Max(0, float64(now.Unix()-state.LastUpdated))
+		tokens := math.Min(capacity, state.Tokens+elapsed*refillRate)
+
+		ctxResult := Context{Limit: tl.rate.Limit}
+		if tokens >= 1 {
+			tokens--
+			state.PendingDelta--
+		} else {
+			ctxResult.Reached = true
+		}
+		ctxResult.Remaining = tokens
+		secondsToNextToken := math.Max(0, math.Ceil((1-tokens)/refillRate))
+		ctxResult.Reset = now.Add(time.Duration(secondsToNextToken) * time.Second)
+
+		state.Tokens, state.LastUpdated = tokens, now.Unix()
+		next, err := json.Marshal(state)
+		return next, ctxResult, err
+	})
+	if err != nil {
+		return Context{}, err
+	}
+
+	tl.markDirty(key)
+	return result.(Context), nil
+}
+
+func (tl *TieredLimiter) markDirty(key string) {
+	tl.dirtyMu.Lock()
+	tl.dirty[key] = struct{}{}
+	tl.dirtyMu.Unlock()
+}
+
+// claimDirtyKeys removes and returns up to max dirty keys (all of them if
+// max <= 0) for the caller to reconcile.
+func (tl *TieredLimiter) claimDirtyKeys(max int) []string {
+	tl.dirtyMu.Lock()
+	defer tl.dirtyMu.Unlock()
+
+	keys := make([]string, 0, len(tl.dirty))
+	for key := range tl.dirty {
+		keys = append(keys, key)
+		delete(tl.dirty, key)
+		if max > 0 && len(keys) >= max {
+			break
+		}
+	}
+	return keys
+}
+
+func (tl *TieredLimiter) flushLoop() {
+	defer tl.wg.Done()
+
+	ticker := time.NewTicker(tl.cfg.FlushInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-tl.stopCh:
+			return
+		case <-ticker.C:
+			tl.flushBatch(context.Background())
+		}
+	}
+}
+
+func (tl *TieredLimiter) flushBatch(ctx context.Context) {
+	for _, key := range tl.claimDirtyKeys(tl.cfg.MaxBatch) {
+		if err := tl.reconcile(ctx, key); err != nil {
+			log.Printf("[TieredLimiter] Failed to reconcile key %q with Redis: %v", key, err)
+			tl.markDirty(key) // retry on the next pass
+		}
+	}
+}
+
+// reconcile snapshots and clears key's pending delta, applies it to the
+// authoritative Redis bucket via tieredFlushScript, and merges the
+// reconciled token count back into the local cache. The merge (rather than
+// an unconditional overwrite) matters because the two local Mutate calls
+// bracket a Redis round trip: a decideLocally debit can land in that window
+// and accumulate its own PendingDelta, and blindly overwriting local state
+// with Redis's answer would silently erase it, both from the cache and from
+// ever reaching Redis.
+func (tl *TieredLimiter) reconcile(ctx context.Context, key string) error {
+	now := time.Now()
+	capacity := float64(tl.rate.Limit)
+
+	var delta float64
+	if _, err := tl.local.Mutate(ctx, key, tl.rate.Period, func(current []byte) ([]byte, interface{}, error) {
+		var state tieredState
+		if len(current) > 0 {
+			if err := json.Unmarshal(current, &state); err != nil {
+				return nil, nil, err
+			}
+		}
+		delta = state.PendingDelta
+		state.PendingDelta = 0
+		next, err := json.Marshal(state)
+		return next, nil, err
+	}); err != nil {
+		return err
+	}
+
+	if delta == 0 {
+		return nil
+	}
+
+	tokensKey, updatedKey := tieredKeys(key)
+	remaining, err := tl.flushScript.Run(ctx, tl.client, []string{tokensKey, updatedKey},
+		capacity, tl.rate.RefillRate(), now.Unix(), periodSeconds(tl.rate), delta).Float64()
+	if err != nil {
+		tl.redisHealthy.Store(false)
+		return err
+	}
+	tl.redisHealthy.Store(true)
+
+	_, err = tl.local.Mutate(ctx, key, tl.rate.Period, func(current []byte) ([]byte, interface{}, error) {
+		var state tieredState
+		if len(current) > 0 {
+			if err := json.Unmarshal(current, &state); err != nil {
+				return nil, nil, err
+			}
+		}
+		// state.PendingDelta here is whatever a concurrent decideLocally
+		// accumulated after the snapshot above claimed `delta` — layer it on
+		// top of Redis's reconciled count instead of discarding it, and keep
+		// it so the next flush still carries it to Redis.
+		tokens := math.Max(0, math.Min(capacity, remaining+state.PendingDelta))
+		next, err := json.Marshal(tieredState{Tokens: tokens, LastUpdated: now.Unix(), PendingDelta: state.PendingDelta})
+		return next, nil, err
+	})
+	return err
+}
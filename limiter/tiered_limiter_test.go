@@ -0,0 +1,161 @@
+package limiter
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/go-redis/redis/v8"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestTieredLimiter_LocalFirstAllowsUpToCapacityBeforeFlush(t *testing.T) {
+	ctx := context.Background()
+	client := redis.NewClient(&redis.Options{Addr: "localhost:6379", DB: 1})
+	defer client.FlushDB(ctx)
+
+	rate := Rate{Limit: 3, Period: 3 * time.Second}
+	tl := NewTieredLimiter(client, rate, TierConfig{
+		Mode:          LocalFirst,
+		FlushInterval: time.Hour, // don't let the background flush interfere
+	})
+	defer tl.Close()
+
+	ip := "192.168.1.400"
+
+	for i := 0; i < int(rate.Limit); i++ {
+		rateLimitCtx, err := tl.Allow(ctx, ip)
+		assert.NoError(t, err)
+		assert.False(t, rateLimitCtx.Reached, "expected request %d to pass", i+1)
+	}
+
+	rateLimitCtx, err := tl.Allow(ctx, ip)
+	assert.NoError(t, err)
+	assert.True(t, rateLimitCtx.Reached, "expected request beyond capacity to be rejected")
+}
+
+func TestTieredLimiter_ReconcilesWithRedisInBackground(t *testing.T) {
+	ctx := context.Background()
+	client := redis.NewClient(&redis.Options{Addr: "localhost:6379", DB: 1})
+	defer client.FlushDB(ctx)
+
+	rate := Rate{Limit: 5, Period: 5 * time.Second}
+	tl := NewTieredLimiter(client, rate, TierConfig{
+		Mode:          LocalFirst,
+		FlushInterval: 20 * time.Millisecond,
+	})
+	defer tl.Close()
+
+	ip := "192.168.1.401"
+	for i := 0; i < 2; i++ {
+		_, err := tl.Allow(ctx, ip)
+		assert.NoError(t, err)
+	}
+
+	time.Sleep(100 * time.Millisecond)
+
+	tokensKey, _ := tieredKeys(ip)
+	remaining, err := client.Get(ctx, tokensKey).Float64()
+	assert.NoError(t, err)
+	assert.InDelta(t, 3, remaining, 0.5, "expected Redis to reflect the 2 locally-consumed tokens")
+}
+
+// delayedScriptClient wraps a RedisClient and sleeps for delay before every
+// Eval/EvalSha call, so a test can force a TieredLimiter flush to block
+// mid-script and land a concurrent local debit in the gap.
+type delayedScriptClient struct {
+	RedisClient
+	delay time.Duration
+}
+
+func (d *delayedScriptClient) Eval(ctx context.Context, script string, keys []string, args ...interface{}) *redis.Cmd {
+	time.Sleep(d.delay)
+	return d.RedisClient.Eval(ctx, script, keys, args...)
+}
+
+func (d *delayedScriptClient) EvalSha(ctx context.Context, sha1 string, keys []string, args ...interface{}) *redis.Cmd {
+	time.Sleep(d.delay)
+	return d.RedisClient.EvalSha(ctx, sha1, keys, args...)
+}
+
+// TestTieredLimiter_ReconcileMergesConcurrentDebitMidFlush proves reconcile's
+// final local-cache write merges rather than overwrites: a decideLocally
+// debit that lands while a flush is blocked in its Redis round trip must
+// survive, both in the local cache and in the next flush to Redis.
+func TestTieredLimiter_ReconcileMergesConcurrentDebitMidFlush(t *testing.T) {
+	ctx := context.Background()
+	client := redis.NewClient(&redis.Options{Addr: "localhost:6379", DB: 1})
+	defer client.FlushDB(ctx)
+
+	rate := Rate{Limit: 10, Period: 10 * time.Second}
+	delayed := &delayedScriptClient{RedisClient: client, delay: 150 * time.Millisecond}
+	tl := NewTieredLimiter(delayed, rate, TierConfig{
+		Mode:          LocalFirst,
+		FlushInterval: time.Hour, // drive reconcile manually below
+	})
+	defer tl.Close()
+
+	ip := "192.168.1.402"
+
+	_, err := tl.Allow(ctx, ip)
+	assert.NoError(t, err)
+
+	done := make(chan error, 1)
+	go func() { done <- tl.reconcile(ctx, ip) }()
+
+	// Give reconcile time to snapshot and clear PendingDelta before the
+	// concurrent debit below lands, while it's still blocked on the delayed
+	// Redis round trip.
+	time.Sleep(50 * time.Millisecond)
+
+	_, err = tl.Allow(ctx, ip)
+	assert.NoError(t, err)
+
+	assert.NoError(t, <-done)
+
+	// Flush again so the second, concurrent debit (which reconcile must have
+	// preserved rather than lost) reaches Redis too.
+	assert.NoError(t, tl.reconcile(ctx, ip))
+
+	tokensKey, _ := tieredKeys(ip)
+	remaining, err := client.Get(ctx, tokensKey).Float64()
+	assert.NoError(t, err)
+	assert.InDelta(t, 8, remaining, 0.5, "expected both debits to reach Redis; the mid-flush debit must not be lost")
+}
+
+// BenchmarkTieredLimiter_LocalFirst measures the local-cache fast path,
+// which never blocks on Redis per request.
+func BenchmarkTieredLimiter_LocalFirst(b *testing.B) {
+	ctx := context.Background()
+	client := redis.NewClient(&redis.Options{Addr: "localhost:6379", DB: 1})
+	defer client.FlushDB(ctx)
+
+	rate := Rate{Limit: int64(b.N) + 1, Period: time.Minute}
+	tl := NewTieredLimiter(client, rate, TierConfig{Mode: LocalFirst, FlushInterval: time.Second})
+	defer tl.Close()
+
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		if _, err := tl.Allow(ctx, "bench-key"); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
+
+// BenchmarkRedisLimiter_PerRequest measures the baseline: a Redis round trip
+// on every single request, for comparison against the local-first tier.
+func BenchmarkRedisLimiter_PerRequest(b *testing.B) {
+	ctx := context.Background()
+	client := redis.NewClient(&redis.Options{Addr: "localhost:6379", DB: 1})
+	defer client.FlushDB(ctx)
+
+	rate := Rate{Limit: int64(b.N) + 1, Period: time.Minute}
+	rateLimiter := NewRedisTokenBucket(client, rate)
+
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		if _, _, _, err := rateLimiter.AllowRequest(ctx, "bench-key"); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
@@ -0,0 +1,63 @@
+package limiter
+
+import (
+	"context"
+	"encoding/json"
+	"math"
+	"time"
+)
+
+// TokenBucket is the original RedisTokenBucket strategy promoted to an
+// Algorithm: each key accrues tokens up to rate.Limit at rate.RefillRate()
+// tokens/sec, and a request is allowed if at least one token is available.
+type TokenBucket struct{}
+
+// NewTokenBucket returns a token-bucket Algorithm.
+func NewTokenBucket() TokenBucket {
+	return TokenBucket{}
+}
+
+type tokenBucketState struct {
+	Tokens      float64 `json:"tokens"`
+	LastUpdated int64   `json:"last_updated"`
+}
+
+// Allow implements Algorithm.
+func (TokenBucket) Allow(ctx context.Context, store Store, key string, rate Rate) (Context, error) {
+	now := time.Now()
+	capacity := float64(rate.Limit)
+	refillRate := rate.RefillRate()
+
+	result, err := store.Mutate(ctx, key, rate.Period, func(current []byte) ([]byte, interface{}, error) {
+		state := tokenBucketState{Tokens: capacity, LastUpdated: now.Unix()}
+		if len(current) > 0 {
+			if err := json.Unmarshal(current, &state); err != nil {
+				return nil, nil, err
+			}
+		}
+
+		elapsed := math.Max(0, float64(now.Unix()-state.LastUpdated))
+		tokens := math.Min(capacity, state.Tokens+elapsed*refillRate)
+
+		ctxResult := Context{Limit: rate.Limit}
+		if tokens >= 1 {
+			tokens--
+		} else {
+			ctxResult.Reached = true
+		}
+		ctxResult.Remaining = tokens
+
+		// Reset is when the next token becomes available, whether or not
+		// this request consumed one, so callers always get an exact time to
+		// render rather than just a boolean.
+		secondsToNextToken := math.Max(0, math.Ceil((1-tokens)/refillRate))
+		ctxResult.Reset = now.Add(time.Duration(secondsToNextToken) * time.Second)
+
+		next, err := json.Marshal(tokenBucketState{Tokens: tokens, LastUpdated: now.Unix()})
+		return next, ctxResult, err
+	})
+	if err != nil {
+		return Context{}, err
+	}
+	return result.(Context), nil
+}
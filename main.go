@@ -2,11 +2,14 @@ package main
 
 import (
 	"context"
+	"crypto/tls"
 	"fmt"
+	"math"
 	"net/http"
 	"os"
 	"os/signal"
 	"strconv"
+	"strings"
 	"syscall"
 	"time"
 	"token-bucket-rate-limiter/limiter"
@@ -25,65 +28,189 @@ func getEnvOrFatal(key string) string {
 	return val
 }
 
+// newRedisClient builds a Redis client for the topology selected by
+// REDIS_MODE (standalone, sentinel, or cluster; standalone is the default),
+// applying shared TLS/AUTH and connection-pool settings to whichever one is
+// chosen.
+func newRedisClient() limiter.RedisClient {
+	mode := os.Getenv("REDIS_MODE")
+	if mode == "" {
+		mode = "standalone"
+	}
+
+	var tlsConfig *tls.Config
+	if os.Getenv("REDIS_TLS") == "true" {
+		tlsConfig = &tls.Config{MinVersion: tls.VersionTLS12}
+	}
+
+	poolSize, _ := strconv.Atoi(os.Getenv("REDIS_POOL_SIZE"))
+	minIdleConns, _ := strconv.Atoi(os.Getenv("REDIS_MIN_IDLE_CONNS"))
+	username := os.Getenv("REDIS_USERNAME")
+	password := os.Getenv("REDIS_PASSWORD")
+
+	switch mode {
+	case "sentinel":
+		return redis.NewFailoverClient(&redis.FailoverOptions{
+			MasterName:    getEnvOrFatal("MASTER_NAME"),
+			SentinelAddrs: strings.Split(getEnvOrFatal("SENTINEL_ADDRS"), ","),
+			Username:      username,
+			Password:      password,
+			TLSConfig:     tlsConfig,
+			PoolSize:      poolSize,
+			MinIdleConns:  minIdleConns,
+		})
+	case "cluster":
+		return redis.NewClusterClient(&redis.ClusterOptions{
+			Addrs:        strings.Split(getEnvOrFatal("REDIS_CLUSTER_ADDRS"), ","),
+			Username:     username,
+			Password:     password,
+			TLSConfig:    tlsConfig,
+			PoolSize:     poolSize,
+			MinIdleConns: minIdleConns,
+		})
+	case "standalone":
+		return redis.NewClient(&redis.Options{
+			Addr:         fmt.Sprintf("%s:%s", getEnvOrFatal("REDIS_HOST"), getEnvOrFatal("REDIS_PORT")),
+			Username:     username,
+			Password:     password,
+			TLSConfig:    tlsConfig,
+			PoolSize:     poolSize,
+			MinIdleConns: minIdleConns,
+			DB:           0,
+		})
+	default:
+		log.WithField("REDIS_MODE", mode).Fatal("Invalid REDIS_MODE, expected standalone, sentinel or cluster")
+		return nil
+	}
+}
+
 func init() {
 	// Configure logrus
 	log.SetFormatter(&log.JSONFormatter{})
 	log.SetOutput(os.Stdout)
 	log.SetLevel(log.InfoLevel)
 
-	// Load environment variables from .env file
+	// Load environment variables from a .env file if present. Its absence
+	// isn't fatal: deployments that inject env vars directly (containers,
+	// systemd units, `go test`) never have one.
 	if err := godotenv.Load(); err != nil {
-		log.WithField("error", err).Fatal("Error loading .env file")
+		log.WithField("error", err).Debug("No .env file loaded")
 	}
 }
 
-// Middleware function to handle rate limiting
-func RateLimitMiddleware(rateLimiter *limiter.RedisTokenBucket, successURL, failureMessage string) gin.HandlerFunc {
-	return func(c *gin.Context) {
-		ctx := c.Request.Context()
-		ip := c.ClientIP()
+// MiddlewareConfig configures RateLimitMiddleware.
+type MiddlewareConfig struct {
+	Client         limiter.RedisClient
+	DefaultRate    limiter.Rate
+	RateSource     limiter.RateSource      // overrides DefaultRate for the default limiter if set, e.g. a DynamicRate
+	RouteRates     map[string]limiter.Rate // overrides DefaultRate, keyed by route pattern (c.FullPath())
+	KeyFunc        KeyFunc                 // defaults to KeyByIP
+	Admission      *limiter.AdmissionList  // optional whitelist/blacklist consulted before rate limiting
+	SuccessURL     string
+	FailureMessage string
+	DisableHeaders bool // suppress X-RateLimit-* and Retry-After headers
+}
+
+// RateLimitMiddleware rate-limits requests by cfg.KeyFunc (client IP by
+// default), using cfg.DefaultRate (or cfg.RateSource, when set) unless the
+// matched route has an override
+// in cfg.RouteRates. Route-overridden keys are scoped to the route so a
+// caller's budget on /api/cheap doesn't eat into its budget on
+// /api/expensive.
+func RateLimitMiddleware(cfg MiddlewareConfig) gin.HandlerFunc {
+	keyFunc := cfg.KeyFunc
+	if keyFunc == nil {
+		keyFunc = KeyByIP
+	}
 
-		if ip == "" {
-			log.WithField("path", c.Request.URL.Path).Error("Unable to determine IP")
-			c.JSON(http.StatusBadRequest, gin.H{"error": "Unable to determine IP"})
+	var defaultLimiter *limiter.RedisTokenBucket
+	if cfg.RateSource != nil {
+		defaultLimiter = limiter.NewRedisTokenBucketWithRateSource(cfg.Client, cfg.RateSource)
+	} else {
+		defaultLimiter = limiter.NewRedisTokenBucket(cfg.Client, cfg.DefaultRate)
+	}
+	routeLimiters := make(map[string]*limiter.RedisTokenBucket, len(cfg.RouteRates))
+	for route, rate := range cfg.RouteRates {
+		routeLimiters[route] = limiter.NewRedisTokenBucket(cfg.Client, rate)
+	}
+
+	return func(c *gin.Context) {
+		key, err := keyFunc(c)
+		if err != nil {
+			log.WithFields(log.Fields{"path": c.Request.URL.Path, "error": err}).Error("Unable to determine rate limit key")
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Unable to determine rate limit key"})
 			c.Abort()
 			return
 		}
 
-		allowed := rateLimiter.AllowRequest(ctx, ip)
+		if cfg.Admission != nil {
+			if cfg.Admission.IsBlacklisted(key) {
+				log.WithFields(log.Fields{"key": key, "path": c.Request.URL.Path}).Info("Blocked blacklisted key")
+				c.JSON(http.StatusForbidden, gin.H{"error": "Forbidden"})
+				c.Abort()
+				return
+			}
+			if cfg.Admission.IsWhitelisted(key) {
+				c.Redirect(http.StatusTemporaryRedirect, cfg.SuccessURL)
+				c.Abort()
+				return
+			}
+		}
+
+		rateLimiter, limiterKey := defaultLimiter, key
+		if rl, ok := routeLimiters[c.FullPath()]; ok {
+			rateLimiter, limiterKey = rl, encodeKeyParts(key, c.FullPath())
+		}
+
+		rateLimitCtx, err := rateLimiter.Allow(c.Request.Context(), limiterKey)
 		logger := log.WithFields(log.Fields{
-			"ip":   ip,
+			"key":  key,
 			"path": c.Request.URL.Path,
 		})
 
-		if !allowed {
-			logger.Info("Rate limit exceeded")
+		if err != nil {
+			logger.WithField("error", err).Error("Rate limiter error")
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Internal server error"})
+			c.Abort()
+			return
+		}
+
+		if !cfg.DisableHeaders {
+			c.Header("X-RateLimit-Limit", strconv.FormatInt(rateLimitCtx.Limit, 10))
+			c.Header("X-RateLimit-Remaining", strconv.FormatInt(int64(math.Max(0, rateLimitCtx.Remaining)), 10))
+			c.Header("X-RateLimit-Reset", strconv.FormatInt(rateLimitCtx.Reset.Unix(), 10))
+		}
+
+		if rateLimitCtx.Reached {
+			retryAfter := time.Until(rateLimitCtx.Reset)
+			logger.WithField("retry_after", retryAfter).Info("Rate limit exceeded")
+			if !cfg.DisableHeaders {
+				c.Header("Retry-After", rateLimitCtx.Reset.UTC().Format(http.TimeFormat))
+			}
 			c.JSON(http.StatusTooManyRequests, gin.H{
-				"error": failureMessage,
-				"ip":    ip,
+				"error":     cfg.FailureMessage,
+				"key":       key,
+				"remaining": rateLimitCtx.Remaining,
 			})
 			c.Abort()
 			return
 		}
 
 		logger.Info("Request allowed")
-		c.Redirect(http.StatusTemporaryRedirect, successURL)
+		c.Redirect(http.StatusTemporaryRedirect, cfg.SuccessURL)
 		c.Abort()
 	}
 }
 
 func main() {
 	// Get and validate environment variables
-	redisHost := getEnvOrFatal("REDIS_HOST")
-	redisPort := getEnvOrFatal("REDIS_PORT")
 	rateLimitStr := getEnvOrFatal("RATE_LIMIT")
-	refillRateStr := getEnvOrFatal("REFILL_RATE")
-	ttlSecondsStr := getEnvOrFatal("TTL_SECONDS")
 	successURL := getEnvOrFatal("SUCCESS_URL")
 	failureMessage := "Rate limit exceeded. Please try again later."
 
-	// Parse numeric values
-	rateLimit, err := strconv.ParseFloat(rateLimitStr, 64)
+	// Parse the compact "<limit>-<period>" rate, e.g. "100-M" for 100
+	// requests per minute.
+	rate, err := limiter.ParseRate(rateLimitStr)
 	if err != nil {
 		log.WithFields(log.Fields{
 			"error": err,
@@ -91,46 +218,50 @@ func main() {
 		}).Fatal("Invalid RATE_LIMIT")
 	}
 
-	refillRate, err := strconv.ParseFloat(refillRateStr, 64)
-	if err != nil {
-		log.WithFields(log.Fields{
-			"error": err,
-			"value": refillRateStr,
-		}).Fatal("Invalid REFILL_RATE")
-	}
-
-	ttlSeconds, err := strconv.Atoi(ttlSecondsStr)
-	if err != nil {
-		log.WithFields(log.Fields{
-			"error": err,
-			"value": ttlSecondsStr,
-		}).Fatal("Invalid TTL_SECONDS")
-	}
-
 	// Initialize Redis client with context
 	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
 	defer cancel()
 
-	client := redis.NewClient(&redis.Options{
-		Addr: fmt.Sprintf("%s:%s", redisHost, redisPort),
-		DB:   0,
-	})
+	client := newRedisClient()
 
 	// Test Redis connection
 	if err := client.Ping(ctx).Err(); err != nil {
 		log.WithField("error", err).Fatal("Failed to connect to Redis")
 	}
 
-	// Initialize rate limiter
-	rateLimiter := limiter.NewRedisTokenBucket(client, rateLimit, refillRate, time.Duration(ttlSeconds)*time.Second)
+	// Admission list and dynamic rate both poll Redis in the background so
+	// operators can whitelist/blacklist IPs or retune capacity without a
+	// restart; Close them on shutdown below.
+	admission := limiter.NewAdmissionList(client, limiter.AdmissionConfig{})
+	defer admission.Close()
+
+	rateSource := limiter.NewDynamicRate(client, rate, limiter.DynamicRateConfig{})
+	defer rateSource.Close()
 
 	// Create Gin router in release mode
 	gin.SetMode(gin.ReleaseMode)
 	r := gin.New()
 	r.Use(gin.Recovery())
 
+	// Admin routes are registered before the rate limiting middleware below,
+	// which redirects or rejects every request it handles, so that admin
+	// requests reach their handlers.
+	if adminToken := os.Getenv("ADMIN_TOKEN"); adminToken != "" {
+		RegisterAdminRoutes(r, admission, adminToken)
+	} else {
+		log.Warn("ADMIN_TOKEN not set, admin endpoints are disabled")
+	}
+
 	// Apply rate limiting middleware with redirect and custom failure message
-	r.Use(RateLimitMiddleware(rateLimiter, successURL, failureMessage))
+	r.Use(RateLimitMiddleware(MiddlewareConfig{
+		Client:         client,
+		DefaultRate:    rate,
+		RateSource:     rateSource,
+		KeyFunc:        KeyByIP,
+		Admission:      admission,
+		SuccessURL:     successURL,
+		FailureMessage: failureMessage,
+	}))
 
 	// Define API routes
 	r.GET("/api", func(c *gin.Context) {